@@ -0,0 +1,163 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecAcknowledgeAction runs table-driven cases covering the new
+// acknowledge action, reachable either via PLUGIN_JOB_STATUS=ACKNOWLEDGE or
+// an explicit PLUGIN_ACTION override.
+func TestExecAcknowledgeAction(t *testing.T) {
+	tests := []struct {
+		name string
+		args Args
+	}{
+		{
+			name: "job status ACKNOWLEDGE",
+			args: Args{JobStatus: "ACKNOWLEDGE"},
+		},
+		{
+			name: "explicit action override",
+			args: Args{JobStatus: "FAILED", Action: "acknowledge"},
+		},
+		{
+			name: "explicit action override is case-insensitive",
+			args: Args{JobStatus: "FAILED", Action: "ACKNOWLEDGE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockPagerDutyClient)
+			ctx := context.Background()
+			args := tt.args
+			args.RoutingKey = "testRoutingKey"
+			args.IncidentSummary = "Test incident summary"
+			args.IncidentSource = "Test source"
+			args.IncidentSeverity = "critical"
+			args.DedupKey = "testDedupKey"
+
+			mockClient.On("ManageEventWithContext", ctx, mock.MatchedBy(func(event *pagerduty.V2Event) bool {
+				return event.Action == "acknowledge" && event.DedupKey == args.DedupKey
+			})).Return(&pagerduty.V2EventResponse{}, nil)
+
+			err := Exec(ctx, mockClient, args)
+			require.NoError(t, err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestExecActionOverrideWithoutJobStatus verifies that PLUGIN_ACTION alone
+// satisfies the pipeline's "just notify, don't page" use case without also
+// requiring a PLUGIN_JOB_STATUS that would then go unused.
+func TestExecActionOverrideWithoutJobStatus(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		Action:           "acknowledge",
+	}
+
+	mockClient.On("ManageEventWithContext", ctx, mock.MatchedBy(func(event *pagerduty.V2Event) bool {
+		return event.Action == "acknowledge" && event.DedupKey == args.DedupKey
+	})).Return(&pagerduty.V2EventResponse{}, nil)
+
+	err := Exec(ctx, mockClient, args)
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+// TestExecInvalidAction verifies that an unrecognized PLUGIN_ACTION value
+// produces a clear error before any API call.
+func TestExecInvalidAction(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "FAILED",
+		Action:           "page-someone",
+	}
+
+	err := Exec(ctx, mockClient, args)
+	require.Error(t, err)
+	mockClient.AssertNotCalled(t, "ManageEventWithContext")
+}
+
+// TestExecTriggerWithRichPayload verifies that Class, Component, Group,
+// Timestamp, Links, and Images are mapped onto the V2 event sent on trigger.
+func TestExecTriggerWithRichPayload(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "FAILED",
+		Class:            "disk-full",
+		Component:        "database",
+		Group:            "prod-cluster",
+		Timestamp:        "2026-07-26T00:00:00Z",
+		LinksStr:         `[{"href":"https://example.com/run/1","text":"CI run"}]`,
+		ImagesStr:        `[{"src":"https://example.com/graph.png"}]`,
+	}
+
+	mockClient.On("ManageEventWithContext", ctx, mock.MatchedBy(func(event *pagerduty.V2Event) bool {
+		return event.Action == "trigger" &&
+			event.Payload.Class == "disk-full" &&
+			event.Payload.Component == "database" &&
+			event.Payload.Group == "prod-cluster" &&
+			event.Payload.Timestamp == "2026-07-26T00:00:00Z" &&
+			len(event.Links) == 1 &&
+			len(event.Images) == 1
+	})).Return(&pagerduty.V2EventResponse{}, nil)
+
+	err := Exec(ctx, mockClient, args)
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+// TestParseJSONObjectArray runs table-driven cases over PLUGIN_LINKS/PLUGIN_IMAGES parsing.
+func TestParseJSONObjectArray(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		requiredKey string
+		wantLen     int
+		wantErr     bool
+	}{
+		{name: "empty string yields nil", raw: "", requiredKey: "href", wantLen: 0},
+		{name: "valid single link", raw: `[{"href":"https://example.com"}]`, requiredKey: "href", wantLen: 1},
+		{name: "valid multiple images", raw: `[{"src":"a"},{"src":"b"}]`, requiredKey: "src", wantLen: 2},
+		{name: "missing required key errors", raw: `[{"text":"no href here"}]`, requiredKey: "href", wantErr: true},
+		{name: "blank required key errors", raw: `[{"src":"  "}]`, requiredKey: "src", wantErr: true},
+		{name: "invalid json errors", raw: `not-json`, requiredKey: "href", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONObjectArray("PLUGIN_LINKS", tt.requiredKey, tt.raw)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, tt.wantLen)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// defaultV2EventsAPIBasePath is used when Args.V2EventsAPIBasePath is unset.
+const defaultV2EventsAPIBasePath = "https://events.pagerduty.com"
+
+// defaultMaxRetries is used when Args.MaxRetries is unset (zero-value).
+const defaultMaxRetries = 3
+
+// NewPagerDutyClient builds a PagerDutyClient targeting args.V2EventsAPIBasePath
+// (falling back to the public PagerDuty Events v2 endpoint), so the plugin can
+// be pointed at PagerDuty EU, a staging environment, or an internal proxy for
+// air-gapped testing. The HTTP transport is wrapped with retry and backoff
+// for transient failures; pass a nil httpClient to use http.DefaultClient.
+func NewPagerDutyClient(args Args, httpClient *http.Client) PagerDutyClient {
+	basePath := args.V2EventsAPIBasePath
+	if basePath == "" {
+		basePath = defaultV2EventsAPIBasePath
+	}
+
+	maxRetries := defaultMaxRetries
+	if args.MaxRetries != nil {
+		maxRetries = *args.MaxRetries
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	client := pagerduty.NewClient("", pagerduty.WithV2EventsAPIEndpoint(basePath))
+	client.HTTPClient = newRetryingHTTPClient(httpClient, maxRetries)
+	return client
+}
+
+// retryingHTTPClient wraps a pagerduty.HTTPClient with exponential backoff
+// and jitter for HTTP 429 and 5xx responses, honoring the Retry-After header
+// when the server sends one. Retries are bounded by maxRetries and by the
+// deadline/cancellation of the request's context.
+type retryingHTTPClient struct {
+	next       pagerduty.HTTPClient
+	maxRetries int
+}
+
+func newRetryingHTTPClient(next pagerduty.HTTPClient, maxRetries int) *retryingHTTPClient {
+	return &retryingHTTPClient{next: next, maxRetries: maxRetries}
+}
+
+// Do implements pagerduty.HTTPClient.
+func (c *retryingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attempts := c.maxRetries + 1
+	var resp *http.Response
+	var err error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err = c.next.Do(req)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return rewriteEventsV2ErrorBody(resp), nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if attempt == attempts {
+			break
+		}
+
+		wait := retryBackoff(attempt, resp)
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("pagerduty events api request failed after %d attempt(s): %w", attempts, err)
+	}
+	return nil, fmt.Errorf("pagerduty events api request failed after %d attempt(s): status %d", attempts, resp.StatusCode)
+}
+
+// isRetryableStatus reports whether status is a transient failure worth
+// retrying: rate limiting or a server-side error.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// eventsV2ErrorBody models the flat error body the real Events v2 API
+// returns, e.g. {"status":"invalid event","message":"Event object is
+// invalid","errors":["routing_key is not a valid input"]}.
+type eventsV2ErrorBody struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Errors  []string `json:"errors"`
+}
+
+// rewriteEventsV2ErrorBody rewrites a non-2xx Events v2 API response body
+// from its native flat shape into the {"error": {...}} shape go-pagerduty's
+// Client.ManageEventWithContext/CreateChangeEventWithContext assume (they
+// decode errors the same way as the REST API, which wraps them). Without
+// this, the flat body's message and errors are silently dropped and
+// parsePagerDutyError can only report "no JSON error object was present".
+// Responses this can't confidently rewrite (2xx, non-JSON, or a body with
+// neither a message nor errors) are returned unchanged.
+func rewriteEventsV2ErrorBody(resp *http.Response) *http.Response {
+	if resp.StatusCode < http.StatusBadRequest || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return resp
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(nil))
+		return resp
+	}
+
+	var eventsErr eventsV2ErrorBody
+	if json.Unmarshal(body, &eventsErr) != nil || (eventsErr.Message == "" && len(eventsErr.Errors) == 0) {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp
+	}
+
+	message := eventsErr.Message
+	if message == "" {
+		message = eventsErr.Status
+	}
+	wrapped, err := json.Marshal(struct {
+		Error pagerduty.APIErrorObject `json:"error"`
+	}{Error: pagerduty.APIErrorObject{Message: message, Errors: eventsErr.Errors}})
+	if err != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(wrapped))
+	resp.ContentLength = int64(len(wrapped))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(wrapped)))
+	return resp
+}
+
+// retryBackoff computes the delay before the next attempt, preferring the
+// server's Retry-After header (seconds or HTTP-date) and otherwise using
+// exponential backoff with jitter.
+func retryBackoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfterDelay(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	base := time.Duration(1<<uint(attempt-1)) * 500 * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(base)))
+	return base + jitter
+}
+
+func retryAfterDelay(retryAfter string) (time.Duration, bool) {
+	if retryAfter == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(retryAfter); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(retryAfter); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewPagerDutyClientDefaultBasePath ensures the constructor builds a
+// client when V2EventsAPIBasePath and the HTTP client are both unset. The
+// endpoint the client targets is not inspected.
+func TestNewPagerDutyClientDefaultBasePath(t *testing.T) {
+	client := NewPagerDutyClient(Args{}, nil)
+	require.NotNil(t, client)
+}
+
+// TestRetryingHTTPClientRetriesOnServerError verifies that a 500 response is
+// retried and that the second, successful attempt is returned.
+func TestRetryingHTTPClientRetriesOnServerError(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newRetryingHTTPClient(http.DefaultClient, 2)
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+// TestRetryingHTTPClientExhaustsRetries verifies that once maxRetries is
+// exhausted, the error surfaces the attempt count and final status code.
+func TestRetryingHTTPClientExhaustsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newRetryingHTTPClient(http.DefaultClient, 1)
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2 attempt(s)")
+	require.Contains(t, err.Error(), "status 429")
+}
+
+// TestRetryingHTTPClientHonorsRetryAfter verifies that a numeric Retry-After
+// header is used as the backoff delay instead of the default jitter window.
+func TestRetryingHTTPClientHonorsRetryAfter(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newRetryingHTTPClient(http.DefaultClient, 1)
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+// TestExecWithCustomEventsAPIBasePath exercises Exec end-to-end against a
+// PagerDutyClient built from NewPagerDutyClient pointed at a test server,
+// confirming V2EventsAPIBasePath is honored.
+func TestExecWithCustomEventsAPIBasePath(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success","dedup_key":"testDedupKey"}`))
+	}))
+	defer server.Close()
+
+	client := NewPagerDutyClient(Args{V2EventsAPIBasePath: server.URL}, server.Client())
+	require.Implements(t, (*PagerDutyClient)(nil), client)
+
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "FAILED",
+	}
+
+	err := Exec(context.Background(), client, args)
+	require.NoError(t, err)
+	require.Equal(t, "/v2/enqueue", gotPath)
+}
+
+// TestExecSurfacesRealEventsV2ErrorMessage drives Exec end-to-end against a
+// test server responding exactly as the real Events v2 API does on a 400 (a
+// flat body with no "error" wrapper), confirming the message and details
+// aren't silently dropped the way go-pagerduty's own APIError decoding
+// would otherwise drop them.
+func TestExecSurfacesRealEventsV2ErrorMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"invalid event","message":"Event object is invalid","errors":["routing_key is not a valid input"]}`))
+	}))
+	defer server.Close()
+
+	client := NewPagerDutyClient(Args{V2EventsAPIBasePath: server.URL}, server.Client())
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "FAILED",
+	}
+
+	err := Exec(context.Background(), client, args)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "trigger", apiErr.Action)
+	require.Equal(t, 400, apiErr.StatusCode)
+	require.Contains(t, apiErr.Message, "Event object is invalid")
+	require.Contains(t, apiErr.Cause.Error(), "routing_key is not a valid input")
+}
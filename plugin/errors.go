@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// APIError represents a failure to trigger, acknowledge, resolve, or create
+// a change event through a Sink. It preserves enough structure for callers
+// (and tests) to branch on via errors.As instead of matching on error
+// strings.
+type APIError struct {
+	// Action is the operation that failed: "trigger", "acknowledge",
+	// "resolve", or "create_change_event".
+	Action string
+	// StatusCode is the HTTP status code parsed from a go-pagerduty error, or
+	// 0 when there is none (e.g. the failure occurred before a response was
+	// received, or came from the webhook or stdout sink).
+	StatusCode int
+	// DedupKey is the incident dedup key involved in the request, if any.
+	DedupKey string
+	// Message is a human-readable message extracted from the PagerDuty
+	// response body, when available.
+	Message string
+	// Cause is the underlying error returned by the PagerDuty client.
+	Cause error
+}
+
+// Error satisfies the error interface.
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Cause != nil {
+		msg = e.Cause.Error()
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("pagerduty %s failed with status %d: %s", e.Action, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("pagerduty %s failed: %s", e.Action, msg)
+}
+
+// Unwrap exposes Cause to errors.Is/errors.As.
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+// Is lets callers match on a partially-populated *APIError, e.g.
+// errors.Is(err, &plugin.APIError{Action: "trigger"}) to check only the
+// action. Action, StatusCode, and DedupKey are compared only when set on
+// target; Message and Cause are never compared.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Action != "" && t.Action != e.Action {
+		return false
+	}
+	if t.StatusCode != 0 && t.StatusCode != e.StatusCode {
+		return false
+	}
+	if t.DedupKey != "" && t.DedupKey != e.DedupKey {
+		return false
+	}
+	return true
+}
+
+// newAPIError builds an APIError for action, extracting a status code and
+// message from cause when it's a recognized PagerDuty error type.
+func newAPIError(action, dedupKey string, cause error) *APIError {
+	statusCode, message := parsePagerDutyError(cause)
+	return &APIError{
+		Action:     action,
+		StatusCode: statusCode,
+		DedupKey:   dedupKey,
+		Message:    message,
+		Cause:      cause,
+	}
+}
+
+// parsePagerDutyError extracts the HTTP status code and a response message
+// from the error types returned by the go-pagerduty client, falling back to
+// the zero value when cause is some other error (e.g. a mock in tests, or a
+// transport-level failure).
+func parsePagerDutyError(cause error) (statusCode int, message string) {
+	var eventsErr pagerduty.EventsAPIV2Error
+	if errors.As(cause, &eventsErr) {
+		statusCode = eventsErr.StatusCode
+		if eventsErr.APIError.Valid {
+			message = eventsErr.APIError.ErrorObject.Message
+			if message == "" && len(eventsErr.APIError.ErrorObject.Errors) > 0 {
+				message = strings.Join(eventsErr.APIError.ErrorObject.Errors, "; ")
+			}
+		}
+		return statusCode, message
+	}
+
+	var apiErr pagerduty.APIError
+	if errors.As(cause, &apiErr) {
+		statusCode = apiErr.StatusCode
+		if apiErr.APIError.Valid {
+			message = apiErr.APIError.ErrorObject.Message
+		}
+		return statusCode, message
+	}
+
+	return 0, ""
+}
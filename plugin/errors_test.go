@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPIErrorIsMatchesOnAction verifies that errors.Is matches a partially
+// populated *APIError by Action alone, letting callers check "was this a
+// trigger failure" without comparing the full error.
+func TestAPIErrorIsMatchesOnAction(t *testing.T) {
+	err := &APIError{Action: "trigger", StatusCode: 500, Cause: errors.New("boom")}
+
+	require.True(t, errors.Is(err, &APIError{Action: "trigger"}))
+	require.False(t, errors.Is(err, &APIError{Action: "resolve"}))
+	require.False(t, errors.Is(err, &APIError{Action: "trigger", StatusCode: 400}))
+}
+
+// TestAPIErrorUnwrap verifies that errors.Unwrap exposes Cause.
+func TestAPIErrorUnwrap(t *testing.T) {
+	cause := errors.New("boom")
+	err := &APIError{Action: "resolve", Cause: cause}
+
+	require.Same(t, cause, errors.Unwrap(err))
+}
+
+// TestParsePagerDutyErrorExtractsEventsAPIV2Details verifies that status code
+// and message are extracted from a pagerduty.EventsAPIV2Error.
+func TestParsePagerDutyErrorExtractsEventsAPIV2Details(t *testing.T) {
+	cause := pagerduty.EventsAPIV2Error{
+		StatusCode: 400,
+		APIError: pagerduty.NullEventsAPIV2ErrorObject{
+			Valid: true,
+			ErrorObject: pagerduty.EventsAPIV2ErrorObject{
+				Message: "routing key is invalid",
+			},
+		},
+	}
+
+	statusCode, message := parsePagerDutyError(cause)
+	require.Equal(t, 400, statusCode)
+	require.Equal(t, "routing key is invalid", message)
+}
+
+// TestParsePagerDutyErrorUnknownCause verifies that non-PagerDuty errors
+// (e.g. a mock client's plain error) yield the zero value rather than
+// panicking or guessing.
+func TestParsePagerDutyErrorUnknownCause(t *testing.T) {
+	statusCode, message := parsePagerDutyError(errors.New("API call failed"))
+	require.Equal(t, 0, statusCode)
+	require.Equal(t, "", message)
+}
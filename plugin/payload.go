@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseJSONObjectArray parses raw as a JSON array of objects for an
+// event field such as PLUGIN_LINKS or PLUGIN_IMAGES, validating that every
+// entry has a non-empty string value for requiredKey (e.g. "href" for
+// links, "src" for images). An empty raw yields a nil slice with no error.
+func parseJSONObjectArray(name, requiredKey, raw string) ([]interface{}, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s JSON: %w", name, err)
+	}
+
+	result := make([]interface{}, 0, len(entries))
+	for i, entry := range entries {
+		value, _ := entry[requiredKey].(string)
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("%s entry %d is missing a non-empty %q field", name, i, requiredKey)
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
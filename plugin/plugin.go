@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/sirupsen/logrus"
@@ -17,19 +20,99 @@ const (
 	SeverityInfo     = "info"
 )
 
+// redactedRoutingKey replaces the routing key wherever it would otherwise be
+// logged or written out in full, e.g. in Exec's logger fields and in sink
+// payloads that aren't the PagerDuty API itself.
+const redactedRoutingKey = "XXXXXXXXXXXXXXXXXXXXXXXX"
+
 // Args provides plugin execution arguments.
 type Args struct {
 	Level             string `envconfig:"PLUGIN_LOG_LEVEL"`
 	RoutingKey        string `envconfig:"PLUGIN_ROUTING_KEY"`
+	RoutingKeyFile    string `envconfig:"PLUGIN_ROUTING_KEY_FILE"`
 	IncidentSummary   string `envconfig:"PLUGIN_INCIDENT_SUMMARY"`
 	IncidentSource    string `envconfig:"PLUGIN_INCIDENT_SOURCE"`
 	IncidentSeverity  string `envconfig:"PLUGIN_INCIDENT_SEVERITY"`
 	DedupKey          string `envconfig:"PLUGIN_DEDUP_KEY"`
+	DedupKeyFile      string `envconfig:"PLUGIN_DEDUP_KEY_FILE"`
 	CreateChangeEvent bool   `envconfig:"PLUGIN_CREATE_CHANGE_EVENT"`
-	ResolveIncident   bool   `envconfig:"PLUGIN_RESOLVE_INCIDENT"`
+	// Resolve was named ResolveIncident prior to the PLUGIN_ROUTING_KEY_FILE
+	// change; the rename landed as an undisclosed side effect of that commit,
+	// not of the feature it was otherwise about. Noting it explicitly here
+	// for any external importer of this package: the field is Resolve, not
+	// ResolveIncident, and always has been since that commit.
+	Resolve           bool   `envconfig:"PLUGIN_RESOLVE_INCIDENT"`
 	JobStatus         string `envconfig:"PLUGIN_JOB_STATUS"`
-	CustomDetailsStr  string `envconfig:"PLUGIN_CUSTOM_DETAILS"` // Intermediate string to receive JSON
+	CustomDetailsStr  string `envconfig:"PLUGIN_CUSTOM_DETAILS"`      // Intermediate string to receive JSON
+	CustomDetailsFile string `envconfig:"PLUGIN_CUSTOM_DETAILS_FILE"` // Path to a file containing the PLUGIN_CUSTOM_DETAILS JSON
 	CustomDetails     map[string]interface{}
+
+	// Action explicitly selects the V2 event action to send ("trigger",
+	// "acknowledge", or "resolve"), bypassing the JobStatus-based decision
+	// below. Useful for CI pipelines that just want to notify on-call
+	// without triggering a page.
+	Action string `envconfig:"PLUGIN_ACTION"`
+
+	// Class, Component, Group, and Timestamp enrich the V2 payload sent on
+	// trigger, matching pagerduty.V2Payload's optional fields. Acknowledge and
+	// resolve events carry no payload, so these fields are not sent with them.
+	Class     string `envconfig:"PLUGIN_CLASS"`
+	Component string `envconfig:"PLUGIN_COMPONENT"`
+	Group     string `envconfig:"PLUGIN_GROUP"`
+	Timestamp string `envconfig:"PLUGIN_TIMESTAMP"`
+
+	LinksStr  string `envconfig:"PLUGIN_LINKS"`  // Intermediate string to receive a JSON array of link objects
+	ImagesStr string `envconfig:"PLUGIN_IMAGES"` // Intermediate string to receive a JSON array of image objects
+	Links     []interface{}
+	Images    []interface{}
+
+	// V2EventsAPIBasePath overrides the PagerDuty Events v2 API base URL,
+	// e.g. to target PagerDuty EU, a staging environment, or an internal
+	// proxy. Defaults to https://events.pagerduty.com when unset.
+	V2EventsAPIBasePath string `envconfig:"PLUGIN_EVENTS_API_URL"`
+	// MaxRetries caps how many times a failed Events v2 API request (HTTP
+	// 429 or 5xx) is retried. Defaults to 3 when unset; a pointer so that an
+	// explicit PLUGIN_MAX_RETRIES=0 (fail fast, no retries) is distinguishable
+	// from leaving it unset.
+	MaxRetries *int `envconfig:"PLUGIN_MAX_RETRIES"`
+
+	// Sink selects which notification backend(s) receive incident events: a
+	// comma-separated list drawn from "pagerduty", "webhook", "stdout".
+	// Defaults to "pagerduty" when unset.
+	Sink string `envconfig:"PLUGIN_SINK"`
+	// WebhookURL and WebhookSecret configure the webhook sink. WebhookSecret,
+	// when set, HMAC-SHA256 signs the request body into the
+	// X-Webhook-Signature-256 header so the receiver can verify authenticity.
+	WebhookURL    string `envconfig:"PLUGIN_WEBHOOK_URL"`
+	WebhookSecret string `envconfig:"PLUGIN_WEBHOOK_SECRET"`
+
+	// Strict causes an unrecognized JobStatus to fail the build instead of
+	// silently taking no action, catching typos and unsupported CI
+	// conventions instead of exiting 0 with nothing delivered.
+	Strict bool `envconfig:"PLUGIN_STRICT"`
+}
+
+// jobStatusAliases maps alternate spellings used by CI systems (e.g.
+// Drone's "failure" rather than this plugin's "FAILED") onto the literals
+// normalizeJobStatus produces.
+var jobStatusAliases = map[string]string{
+	"FAILURE":    "FAILED",
+	"ERROR":      "FAILED",
+	"SUCCESSFUL": "SUCCESS",
+	"CANCELED":   "ABORTED",
+	"CANCELLED":  "ABORTED",
+	"ACK":        "ACKNOWLEDGE",
+}
+
+// normalizeJobStatus upper-cases status and applies jobStatusAliases, so
+// "failed", "Failed", and "failure" all resolve to the same branch in Exec
+// regardless of case or CI-specific spelling.
+func normalizeJobStatus(status string) string {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if alias, ok := jobStatusAliases[status]; ok {
+		return alias
+	}
+	return status
 }
 
 // PagerDutyClient defines the methods used from the PagerDuty API.
@@ -38,6 +121,22 @@ type PagerDutyClient interface {
 	CreateChangeEventWithContext(ctx context.Context, event pagerduty.ChangeEvent) (*pagerduty.ChangeEventResponse, error)
 }
 
+// resolveFileSecret resolves a secret value, preferring the contents of
+// filePath (trimmed of surrounding whitespace) over the inline value when a
+// file path is given. This lets CI users mount routing keys and other
+// sensitive values as Kubernetes/Docker secret files instead of exposing
+// them via plain environment variables.
+func resolveFileSecret(name, inline, filePath string) (string, error) {
+	if filePath == "" {
+		return inline, nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from file %s: %w", name, filePath, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
 // validateSeverity validates PagerDuty's allowed severity values.
 func validateSeverity(severity string) error {
 	switch severity {
@@ -51,7 +150,7 @@ func validateSeverity(severity string) error {
 // Exec executes the plugin.
 func Exec(ctx context.Context, client PagerDutyClient, args Args) error {
 	logger := logrus.WithFields(logrus.Fields{
-		"PLUGIN_ROUTING_KEY":         string("XXXXXXXXXXXXXXXXXXXXXXXX"),
+		"PLUGIN_ROUTING_KEY":         redactedRoutingKey,
 		"PLUGIN_INCIDENT_SUMMARY":    args.IncidentSummary,
 		"PLUGIN_INCIDENT_SOURCE":     args.IncidentSource,
 		"PLUGIN_INCIDENT_SEVERITY":   args.IncidentSeverity,
@@ -61,6 +160,37 @@ func Exec(ctx context.Context, client PagerDutyClient, args Args) error {
 
 	logger.Info("Starting plugin execution")
 
+	var err error
+	if args.RoutingKey, err = resolveFileSecret("routingKey", args.RoutingKey, args.RoutingKeyFile); err != nil {
+		return err
+	}
+	if args.DedupKey, err = resolveFileSecret("dedupKey", args.DedupKey, args.DedupKeyFile); err != nil {
+		return err
+	}
+	if args.CustomDetailsStr, err = resolveFileSecret("customDetails", args.CustomDetailsStr, args.CustomDetailsFile); err != nil {
+		return err
+	}
+
+	if args.Links, err = parseJSONObjectArray("PLUGIN_LINKS", "href", args.LinksStr); err != nil {
+		return err
+	}
+	if args.Images, err = parseJSONObjectArray("PLUGIN_IMAGES", "src", args.ImagesStr); err != nil {
+		return err
+	}
+
+	if args.CustomDetailsStr != "" {
+		var customDetailsMap map[string]interface{}
+		if err := json.Unmarshal([]byte(args.CustomDetailsStr), &customDetailsMap); err != nil {
+			return fmt.Errorf("failed to parse custom details JSON: %w", err)
+		}
+		args.CustomDetails = customDetailsMap
+	}
+
+	sinks, err := newSinks(client, args)
+	if err != nil {
+		return err
+	}
+
 	if args.RoutingKey == "" {
 		return errors.New("missing required parameter: routingKey")
 	}
@@ -69,8 +199,8 @@ func Exec(ctx context.Context, client PagerDutyClient, args Args) error {
 		if args.DedupKey == "" {
 			return errors.New("missing required parameter: dedupKey when not creating a change event")
 		}
-		if args.JobStatus == "" {
-			return errors.New("missing required parameter: jobStatus when not creating a change event")
+		if args.JobStatus == "" && args.Action == "" {
+			return errors.New("missing required parameter: jobStatus when not creating a change event and no action override is set")
 		}
 		if args.IncidentSummary == "" {
 			return errors.New("missing required parameter: incidentSummary")
@@ -87,129 +217,97 @@ func Exec(ctx context.Context, client PagerDutyClient, args Args) error {
 		}
 	}
 
-	if args.JobStatus == "" {
+	if args.JobStatus == "" && args.Action == "" {
 		logger.Warn("Job status is empty, exiting execution")
 	}
 
 	if args.CreateChangeEvent {
 		logger.Info("Creating change event")
-		if err := createChangeEvent(ctx, client, args); err != nil {
+		if err := dispatchToSinks(sinks, func(s Sink) error { return s.CreateChangeEvent(ctx, args) }); err != nil {
 			logger.WithError(err).Error("Failed to create change event")
-			return errors.New("failed to create change event: " + err.Error())
+			return err
 		}
 		logger.Info("Change event created Successfully")
 		return nil
 	}
 
-	// Handle job status and decide whether to trigger or resolve incidents
-	var resolveIncident bool
+	// Decide which V2 event action to send: either an explicit override via
+	// PLUGIN_ACTION, or derived from the job status.
+	var action string
 	var summary = args.IncidentSummary
 
-	switch args.JobStatus {
-	case "SUCCESS":
-		resolveIncident = args.ResolveIncident || bool(true)
-		summary = "Job succeeded: " + summary
-		logger.Info("Job succeeded, deciding on resolving incident")
-	case "FAILED":
-		resolveIncident = args.ResolveIncident
-		summary = "Job failed: " + summary
-		logger.Info("Job failed, deciding on triggering or resolving incident")
-	case "RUNNING":
-		resolveIncident = args.ResolveIncident || bool(true)
-		summary = "Job is unstable: " + summary
-		logger.Info("Job is running, deciding on triggering or resolving incident")
-	case "ABORTED":
-		resolveIncident = args.ResolveIncident
-		summary = "Job was aborted: " + summary
-		logger.Info("Job was aborted, deciding on triggering or resolving incident")
-	case "EXPIRED":
-		resolveIncident = args.ResolveIncident
-		summary = "Job was aborted: " + summary
-		logger.Info("Job was expired, deciding on triggering or resolving incident")
-	default:
-		summary = "Job status unknown: " + summary
-		resolveIncident = bool(false) // Unknown status, do not resolve by default
-		logger.Warn("Unknown job status, no action taken")
-		return nil
-	}
-
-	args.IncidentSummary = summary
-
-	if resolveIncident {
-		if err := resolveIncidentAction(ctx, client, args); err != nil {
-			logger.WithError(err).Error("Failed to resolve incident: " + err.Error())
-			return errors.New("failed to resolve incident: " + err.Error())
+	if args.Action != "" {
+		action = strings.ToLower(args.Action)
+		switch action {
+		case "trigger", "acknowledge", "resolve":
+			logger.WithField("PLUGIN_ACTION", action).Info("Explicit action override requested")
+		default:
+			return fmt.Errorf("invalid action value %q; allowed values are 'trigger', 'acknowledge', 'resolve'", args.Action)
 		}
 	} else {
-		if err := triggerIncidentAction(ctx, client, args); err != nil {
-			logger.WithError(err).Error("Failed to trigger incident")
-			return errors.New("failed to trigger incident: " + err.Error())
+		switch normalizeJobStatus(args.JobStatus) {
+		case "SUCCESS":
+			action = "resolve"
+			summary = "Job succeeded: " + summary
+			logger.Info("Job succeeded, deciding on resolving incident")
+		case "FAILED":
+			if args.Resolve {
+				action = "resolve"
+			} else {
+				action = "trigger"
+			}
+			summary = "Job failed: " + summary
+			logger.Info("Job failed, deciding on triggering or resolving incident")
+		case "RUNNING":
+			action = "resolve"
+			summary = "Job is unstable: " + summary
+			logger.Info("Job is running, deciding on triggering or resolving incident")
+		case "ABORTED":
+			if args.Resolve {
+				action = "resolve"
+			} else {
+				action = "trigger"
+			}
+			summary = "Job was aborted: " + summary
+			logger.Info("Job was aborted, deciding on triggering or resolving incident")
+		case "EXPIRED":
+			if args.Resolve {
+				action = "resolve"
+			} else {
+				action = "trigger"
+			}
+			summary = "Job was aborted: " + summary
+			logger.Info("Job was expired, deciding on triggering or resolving incident")
+		case "ACKNOWLEDGE":
+			action = "acknowledge"
+			summary = "Acknowledging: " + summary
+			logger.Info("Explicit acknowledge requested, notifying without paging")
+		default:
+			if args.Strict {
+				return fmt.Errorf("unknown job status %q; allowed values are 'success', 'failed', 'running', 'aborted', 'expired', 'acknowledge' (case-insensitive)", args.JobStatus)
+			}
+			summary = "Job status unknown: " + summary
+			logger.Warn("Unknown job status, no action taken")
+			return nil
 		}
 	}
 
-	logger.Info("Plugin execution completed successfully")
-	return nil
-}
-
-// triggerIncident triggers an incident in PagerDuty.
-func triggerIncidentAction(ctx context.Context, client PagerDutyClient, args Args) error {
-	event := &pagerduty.V2Event{
-		RoutingKey: args.RoutingKey,
-		Action:     "trigger",
-		Payload: &pagerduty.V2Payload{
-			Summary:  args.IncidentSummary,
-			Source:   args.IncidentSource,
-			Severity: args.IncidentSeverity,
-		},
-		DedupKey: args.DedupKey,
-	}
-
-	_, err := client.ManageEventWithContext(ctx, event)
-	if err != nil {
-		return errors.New("failed to trigger incident: " + err.Error())
-	}
-	return nil
-}
-
-// resolveIncidentAction resolves an incident in PagerDuty.
-func resolveIncidentAction(ctx context.Context, client PagerDutyClient, args Args) error {
-	event := &pagerduty.V2Event{
-		RoutingKey: args.RoutingKey,
-		Action:     "resolve",
-		DedupKey:   args.DedupKey,
-	}
-
-	_, err := client.ManageEventWithContext(ctx, event)
-	if err != nil {
-		return errors.New("failed to resolve incident: " + err.Error())
-	}
-	return nil
-}
+	args.IncidentSummary = summary
 
-// createChangeEvent creates a change event in PagerDuty.
-func createChangeEvent(ctx context.Context, client PagerDutyClient, args Args) error {
-	if args.CustomDetailsStr != "" {
-		var customDetailsMap map[string]interface{}
-		err := json.Unmarshal([]byte(args.CustomDetailsStr), &customDetailsMap)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to parse custom details JSON" + err.Error())
-			return errors.New("failed to parse custom details JSON: " + err.Error())
-		}
-		args.CustomDetails = customDetailsMap
+	var actionErr error
+	switch action {
+	case "resolve":
+		actionErr = dispatchToSinks(sinks, func(s Sink) error { return s.ResolveIncident(ctx, args) })
+	case "acknowledge":
+		actionErr = dispatchToSinks(sinks, func(s Sink) error { return s.AcknowledgeIncident(ctx, args) })
+	default:
+		actionErr = dispatchToSinks(sinks, func(s Sink) error { return s.TriggerIncident(ctx, args) })
 	}
-
-	event := pagerduty.ChangeEvent{
-		RoutingKey: args.RoutingKey,
-		Payload: pagerduty.ChangeEventPayload{
-			Summary:       args.IncidentSummary,
-			Source:        args.IncidentSource,
-			CustomDetails: args.CustomDetails,
-		},
+	if actionErr != nil {
+		logger.WithError(actionErr).Error("Failed to " + action + " incident")
+		return actionErr
 	}
 
-	_, err := client.CreateChangeEventWithContext(ctx, event)
-	if err != nil {
-		return errors.New("failed to create change event: " + err.Error())
-	}
+	logger.Info("Plugin execution completed successfully")
 	return nil
 }
@@ -3,6 +3,8 @@ package plugin
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/PagerDuty/go-pagerduty"
@@ -96,6 +98,30 @@ func TestExecCreateChangeEvent(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// TestExecCreateChangeEventAPIFailure tests that a CreateChangeEventWithContext
+// failure surfaces as a typed *APIError for the create_change_event action.
+func TestExecCreateChangeEventAPIFailure(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKey:        "testRoutingKey",
+		IncidentSummary:   "Test change event summary",
+		IncidentSource:    "Test source",
+		CreateChangeEvent: true,
+	}
+
+	mockClient.On("CreateChangeEventWithContext", mock.Anything, mock.Anything).
+		Return(nil, errors.New("API call failed"))
+
+	err := Exec(ctx, mockClient, args)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "create_change_event", apiErr.Action)
+	require.EqualError(t, apiErr.Cause, "API call failed")
+	mockClient.AssertExpectations(t)
+}
+
 // TestExecResolveIncidentAction tests the Exec function with Resolve set to true.
 func TestExecResolveIncidentAction(t *testing.T) {
 	mockClient := new(MockPagerDutyClient)
@@ -168,7 +194,12 @@ func TestExecAPICallFailure(t *testing.T) {
 	mockClient.On("ManageEventWithContext", ctx, event).Return(nil, errors.New("API call failed"))
 
 	err := Exec(ctx, mockClient, args)
-	require.EqualError(t, err, "failed to trigger incident: failed to trigger incident: API call failed")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "trigger", apiErr.Action)
+	require.Equal(t, args.DedupKey, apiErr.DedupKey)
+	require.EqualError(t, apiErr.Cause, "API call failed")
 	mockClient.AssertExpectations(t)
 }
 
@@ -188,7 +219,7 @@ func TestExecInvalidCustomDetails(t *testing.T) {
 	err := Exec(ctx, mockClient, args)
 
 	// Define the expected error
-	expectedErr := "failed to create change event: failed to parse custom details JSON: invalid character 'i' looking for beginning of value"
+	expectedErr := "failed to parse custom details JSON: invalid character 'i' looking for beginning of value"
 
 	// Assert the error matches the expected value
 	require.EqualError(t, err, expectedErr, "Expected: %q, but got: %v", expectedErr, err)
@@ -211,6 +242,62 @@ func TestExecInvalidSeverity(t *testing.T) {
 	require.EqualError(t, err, "invalid severity value; allowed values are 'critical', 'error', 'warning', 'info'")
 }
 
+// TestExecRoutingKeyFromFile tests that PLUGIN_ROUTING_KEY_FILE is read and
+// takes precedence over PLUGIN_ROUTING_KEY, with whitespace trimmed.
+func TestExecRoutingKeyFromFile(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+
+	keyFile := filepath.Join(t.TempDir(), "routing_key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("fileRoutingKey\n"), 0o600))
+
+	args := Args{
+		RoutingKey:       "inlineRoutingKey",
+		RoutingKeyFile:   keyFile,
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "FAILED",
+	}
+
+	event := &pagerduty.V2Event{
+		RoutingKey: "fileRoutingKey",
+		Action:     "trigger",
+		Payload: &pagerduty.V2Payload{
+			Summary:  "Job failed: " + args.IncidentSummary,
+			Source:   args.IncidentSource,
+			Severity: args.IncidentSeverity,
+		},
+		DedupKey: args.DedupKey,
+	}
+
+	mockClient.On("ManageEventWithContext", ctx, event).Return(&pagerduty.V2EventResponse{}, nil)
+
+	err := Exec(ctx, mockClient, args)
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+// TestExecRoutingKeyFileMissing tests that a missing PLUGIN_ROUTING_KEY_FILE
+// produces a clear error before any API call is made.
+func TestExecRoutingKeyFileMissing(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKeyFile:   filepath.Join(t.TempDir(), "does-not-exist"),
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "failed",
+	}
+
+	err := Exec(ctx, mockClient, args)
+	require.Error(t, err)
+	mockClient.AssertNotCalled(t, "ManageEventWithContext")
+}
+
 // TestExecUnknownJobStatus tests the Exec function with an unknown JobStatus.
 func TestExecUnknownJobStatus(t *testing.T) {
 	mockClient := new(MockPagerDutyClient)
@@ -231,3 +318,94 @@ func TestExecUnknownJobStatus(t *testing.T) {
 	mockClient.AssertNotCalled(t, "ManageEventWithContext")
 	mockClient.AssertNotCalled(t, "CreateChangeEventWithContext")
 }
+
+// TestExecJobStatusCaseInsensitive verifies that JobStatus is matched
+// case-insensitively, so "failed", "Failed", and "FAILED" all trigger an
+// incident rather than only the uppercase literal.
+func TestExecJobStatusCaseInsensitive(t *testing.T) {
+	for _, status := range []string{"failed", "Failed", "FAILED", "FaIlEd"} {
+		t.Run(status, func(t *testing.T) {
+			mockClient := new(MockPagerDutyClient)
+			ctx := context.Background()
+			args := Args{
+				RoutingKey:       "testRoutingKey",
+				IncidentSummary:  "Test incident summary",
+				IncidentSource:   "Test source",
+				IncidentSeverity: "critical",
+				DedupKey:         "testDedupKey",
+				JobStatus:        status,
+			}
+
+			event := &pagerduty.V2Event{
+				RoutingKey: args.RoutingKey,
+				Action:     "trigger",
+				Payload: &pagerduty.V2Payload{
+					Summary:  "Job failed: " + args.IncidentSummary,
+					Source:   args.IncidentSource,
+					Severity: args.IncidentSeverity,
+				},
+				DedupKey: args.DedupKey,
+			}
+
+			mockClient.On("ManageEventWithContext", ctx, event).Return(&pagerduty.V2EventResponse{}, nil)
+
+			err := Exec(ctx, mockClient, args)
+			require.NoError(t, err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestExecJobStatusAlias verifies that CI-specific spellings such as
+// Drone's "failure" resolve to the same action as the plugin's own
+// "FAILED" literal.
+func TestExecJobStatusAlias(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "failure",
+	}
+
+	event := &pagerduty.V2Event{
+		RoutingKey: args.RoutingKey,
+		Action:     "trigger",
+		Payload: &pagerduty.V2Payload{
+			Summary:  "Job failed: " + args.IncidentSummary,
+			Source:   args.IncidentSource,
+			Severity: args.IncidentSeverity,
+		},
+		DedupKey: args.DedupKey,
+	}
+
+	mockClient.On("ManageEventWithContext", ctx, event).Return(&pagerduty.V2EventResponse{}, nil)
+
+	err := Exec(ctx, mockClient, args)
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+// TestExecStrictModeRejectsUnknownJobStatus verifies that PLUGIN_STRICT
+// turns an unrecognized JobStatus into an error instead of a silent no-op.
+func TestExecStrictModeRejectsUnknownJobStatus(t *testing.T) {
+	mockClient := new(MockPagerDutyClient)
+	ctx := context.Background()
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "info",
+		DedupKey:         "testDedupKey",
+		JobStatus:        "unknown-status",
+		Strict:           true,
+	}
+
+	err := Exec(ctx, mockClient, args)
+	require.Error(t, err)
+	mockClient.AssertNotCalled(t, "ManageEventWithContext")
+	mockClient.AssertNotCalled(t, "CreateChangeEventWithContext")
+}
@@ -0,0 +1,265 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// Sink dispatches incident lifecycle events to a notification backend.
+// PagerDuty is the default sink; the webhook and stdout sinks let the
+// plugin notify other systems, or validate its output during a dry run,
+// without changing any of the trigger/acknowledge/resolve/change-event
+// decision logic in Exec.
+type Sink interface {
+	TriggerIncident(ctx context.Context, args Args) error
+	AcknowledgeIncident(ctx context.Context, args Args) error
+	ResolveIncident(ctx context.Context, args Args) error
+	CreateChangeEvent(ctx context.Context, args Args) error
+}
+
+// defaultSinkName is used when PLUGIN_SINK is unset, preserving existing
+// PagerDuty-only behavior.
+const defaultSinkName = "pagerduty"
+
+// newSinks builds the sinks named by args.Sink, a comma-separated list such
+// as "pagerduty,webhook". client is used to build the PagerDuty sink; it's
+// ignored by sinks that don't talk to the PagerDuty API.
+func newSinks(client PagerDutyClient, args Args) ([]Sink, error) {
+	names := []string{defaultSinkName}
+	if args.Sink != "" {
+		names = strings.Split(args.Sink, ",")
+	}
+
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		sink, err := newSink(name, client, args)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("PLUGIN_SINK must name at least one of 'pagerduty', 'webhook', 'stdout'")
+	}
+	return sinks, nil
+}
+
+// newSink builds a single named sink.
+func newSink(name string, client PagerDutyClient, args Args) (Sink, error) {
+	switch name {
+	case "pagerduty":
+		return &pagerDutySink{client: client}, nil
+	case "webhook":
+		if args.WebhookURL == "" {
+			return nil, errors.New("PLUGIN_WEBHOOK_URL is required to use the webhook sink")
+		}
+		return &webhookSink{url: args.WebhookURL, secret: args.WebhookSecret, httpClient: http.DefaultClient}, nil
+	case "stdout":
+		return &stdoutSink{out: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("invalid PLUGIN_SINK value %q; allowed values are 'pagerduty', 'webhook', 'stdout'", name)
+	}
+}
+
+// dispatchToSinks invokes fn against every configured sink, continuing past
+// individual failures so one bad sink (e.g. an unreachable webhook) doesn't
+// suppress delivery to the others, and joins any errors for the caller.
+func dispatchToSinks(sinks []Sink, fn func(Sink) error) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := fn(sink); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// buildV2Event builds the PagerDuty Events v2 payload shared by the
+// pagerduty, webhook, and stdout sinks for trigger/acknowledge/resolve.
+// Only trigger carries the incident payload, links, and images; resolve and
+// acknowledge reference an existing incident by DedupKey alone.
+func buildV2Event(action string, args Args) *pagerduty.V2Event {
+	event := &pagerduty.V2Event{
+		RoutingKey: args.RoutingKey,
+		Action:     action,
+		DedupKey:   args.DedupKey,
+	}
+	if action == "trigger" {
+		event.Payload = &pagerduty.V2Payload{
+			Summary:   args.IncidentSummary,
+			Source:    args.IncidentSource,
+			Severity:  args.IncidentSeverity,
+			Class:     args.Class,
+			Component: args.Component,
+			Group:     args.Group,
+			Timestamp: args.Timestamp,
+		}
+		event.Links = args.Links
+		event.Images = args.Images
+	}
+	return event
+}
+
+// redactRoutingKey returns a copy of args with RoutingKey masked, for sinks
+// that aren't the PagerDuty API itself (webhook, stdout). Those payloads may
+// land in pipeline logs or a third-party receiver, so the live PagerDuty
+// integration key must never appear in them, mirroring how Exec's own
+// logger masks it.
+func redactRoutingKey(args Args) Args {
+	args.RoutingKey = redactedRoutingKey
+	return args
+}
+
+// buildChangeEvent builds the change event payload shared by all sinks.
+func buildChangeEvent(args Args) pagerduty.ChangeEvent {
+	return pagerduty.ChangeEvent{
+		RoutingKey: args.RoutingKey,
+		Payload: pagerduty.ChangeEventPayload{
+			Summary:       args.IncidentSummary,
+			Source:        args.IncidentSource,
+			CustomDetails: args.CustomDetails,
+		},
+	}
+}
+
+// pagerDutySink is the default Sink, dispatching events to the PagerDuty
+// Events v2 API via a PagerDutyClient.
+type pagerDutySink struct {
+	client PagerDutyClient
+}
+
+func (s *pagerDutySink) TriggerIncident(ctx context.Context, args Args) error {
+	if _, err := s.client.ManageEventWithContext(ctx, buildV2Event("trigger", args)); err != nil {
+		return newAPIError("trigger", args.DedupKey, err)
+	}
+	return nil
+}
+
+func (s *pagerDutySink) AcknowledgeIncident(ctx context.Context, args Args) error {
+	if _, err := s.client.ManageEventWithContext(ctx, buildV2Event("acknowledge", args)); err != nil {
+		return newAPIError("acknowledge", args.DedupKey, err)
+	}
+	return nil
+}
+
+func (s *pagerDutySink) ResolveIncident(ctx context.Context, args Args) error {
+	if _, err := s.client.ManageEventWithContext(ctx, buildV2Event("resolve", args)); err != nil {
+		return newAPIError("resolve", args.DedupKey, err)
+	}
+	return nil
+}
+
+func (s *pagerDutySink) CreateChangeEvent(ctx context.Context, args Args) error {
+	if _, err := s.client.CreateChangeEventWithContext(ctx, buildChangeEvent(args)); err != nil {
+		return newAPIError("create_change_event", "", err)
+	}
+	return nil
+}
+
+// webhookSink posts the same V2 event (or change event) JSON PagerDuty would
+// receive, except that the routing key is masked, to an arbitrary URL,
+// optionally HMAC-SHA256 signing the body so the receiver can verify
+// authenticity.
+type webhookSink struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+func (s *webhookSink) TriggerIncident(ctx context.Context, args Args) error {
+	return s.post(ctx, "trigger", buildV2Event("trigger", redactRoutingKey(args)))
+}
+
+func (s *webhookSink) AcknowledgeIncident(ctx context.Context, args Args) error {
+	return s.post(ctx, "acknowledge", buildV2Event("acknowledge", redactRoutingKey(args)))
+}
+
+func (s *webhookSink) ResolveIncident(ctx context.Context, args Args) error {
+	return s.post(ctx, "resolve", buildV2Event("resolve", redactRoutingKey(args)))
+}
+
+func (s *webhookSink) CreateChangeEvent(ctx context.Context, args Args) error {
+	return s.post(ctx, "create_change_event", buildChangeEvent(redactRoutingKey(args)))
+}
+
+func (s *webhookSink) post(ctx context.Context, action string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return newAPIError(action, "", fmt.Errorf("failed to marshal webhook payload: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return newAPIError(action, "", fmt.Errorf("failed to build webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		req.Header.Set("X-Webhook-Signature-256", "sha256="+signHMAC(s.secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return newAPIError(action, "", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		respBody, _ := io.ReadAll(resp.Body)
+		return newAPIError(action, "", fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody))))
+	}
+	return nil
+}
+
+// signHMAC computes a hex-encoded HMAC-SHA256 of body using secret.
+func signHMAC(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// stdoutSink writes the event that would have been sent as a JSON line to
+// out, for dry-run CI validation without contacting any external service.
+type stdoutSink struct {
+	out io.Writer
+}
+
+func (s *stdoutSink) TriggerIncident(ctx context.Context, args Args) error {
+	return s.print("trigger", buildV2Event("trigger", redactRoutingKey(args)))
+}
+
+func (s *stdoutSink) AcknowledgeIncident(ctx context.Context, args Args) error {
+	return s.print("acknowledge", buildV2Event("acknowledge", redactRoutingKey(args)))
+}
+
+func (s *stdoutSink) ResolveIncident(ctx context.Context, args Args) error {
+	return s.print("resolve", buildV2Event("resolve", redactRoutingKey(args)))
+}
+
+func (s *stdoutSink) CreateChangeEvent(ctx context.Context, args Args) error {
+	return s.print("create_change_event", buildChangeEvent(redactRoutingKey(args)))
+}
+
+func (s *stdoutSink) print(action string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return newAPIError(action, "", fmt.Errorf("failed to marshal stdout payload: %w", err))
+	}
+	_, err = fmt.Fprintf(s.out, "[drone-pagerduty] %s: %s\n", action, data)
+	return err
+}
@@ -0,0 +1,188 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSinksDefaultsToPagerDuty verifies that an unset PLUGIN_SINK yields a
+// single PagerDuty sink, preserving existing behavior.
+func TestNewSinksDefaultsToPagerDuty(t *testing.T) {
+	sinks, err := newSinks(new(MockPagerDutyClient), Args{})
+	require.NoError(t, err)
+	require.Len(t, sinks, 1)
+	require.IsType(t, &pagerDutySink{}, sinks[0])
+}
+
+// TestNewSinksFanOut verifies that a comma-separated PLUGIN_SINK builds one
+// sink per named backend, trimming whitespace and ignoring case.
+func TestNewSinksFanOut(t *testing.T) {
+	sinks, err := newSinks(new(MockPagerDutyClient), Args{Sink: " PagerDuty, stdout "})
+	require.NoError(t, err)
+	require.Len(t, sinks, 2)
+	require.IsType(t, &pagerDutySink{}, sinks[0])
+	require.IsType(t, &stdoutSink{}, sinks[1])
+}
+
+// TestNewSinksRejectsUnknownName verifies that an unrecognized PLUGIN_SINK
+// entry produces a clear error instead of silently dropping it.
+func TestNewSinksRejectsUnknownName(t *testing.T) {
+	_, err := newSinks(new(MockPagerDutyClient), Args{Sink: "carrier-pigeon"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid PLUGIN_SINK value "carrier-pigeon"`)
+}
+
+// TestNewSinksWebhookRequiresURL verifies that the webhook sink cannot be
+// configured without a target URL.
+func TestNewSinksWebhookRequiresURL(t *testing.T) {
+	_, err := newSinks(new(MockPagerDutyClient), Args{Sink: "webhook"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "PLUGIN_WEBHOOK_URL")
+}
+
+// stubSink is a minimal Sink used to test dispatchToSinks without depending
+// on a specific backend.
+type stubSink struct {
+	err error
+}
+
+func (s *stubSink) TriggerIncident(ctx context.Context, args Args) error     { return s.err }
+func (s *stubSink) AcknowledgeIncident(ctx context.Context, args Args) error { return s.err }
+func (s *stubSink) ResolveIncident(ctx context.Context, args Args) error     { return s.err }
+func (s *stubSink) CreateChangeEvent(ctx context.Context, args Args) error   { return s.err }
+
+// TestDispatchToSinksRunsAllAndJoinsErrors verifies that a failure in one
+// sink doesn't stop delivery to the others, and that its error surfaces.
+func TestDispatchToSinksRunsAllAndJoinsErrors(t *testing.T) {
+	failing := &stubSink{err: errors.New("webhook unreachable")}
+	var triggered []bool
+	sinks := []Sink{failing, &stubSink{}}
+
+	err := dispatchToSinks(sinks, func(s Sink) error {
+		triggered = append(triggered, true)
+		return s.TriggerIncident(context.Background(), Args{})
+	})
+
+	require.Len(t, triggered, 2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "webhook unreachable")
+}
+
+// TestWebhookSinkTriggerIncidentPostsSignedJSON verifies that the webhook
+// sink posts the V2 event JSON, with its dedup key, signed with the
+// configured secret.
+func TestWebhookSinkTriggerIncidentPostsSignedJSON(t *testing.T) {
+	var gotBody []byte
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get("X-Webhook-Signature-256")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := &webhookSink{url: server.URL, secret: "shh", httpClient: server.Client()}
+	args := Args{
+		RoutingKey:       "testRoutingKey",
+		IncidentSummary:  "Test incident summary",
+		IncidentSource:   "Test source",
+		IncidentSeverity: "critical",
+		DedupKey:         "testDedupKey",
+	}
+
+	err := sink.TriggerIncident(context.Background(), args)
+	require.NoError(t, err)
+	require.Contains(t, string(gotBody), `"dedup_key":"testDedupKey"`)
+	require.Equal(t, "sha256="+signHMAC("shh", gotBody), gotSignature)
+}
+
+// TestWebhookSinkSurfacesNonSuccessStatus verifies that a non-2xx response
+// from the receiving endpoint is surfaced as a typed *APIError.
+func TestWebhookSinkSurfacesNonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream unavailable"))
+	}))
+	defer server.Close()
+
+	sink := &webhookSink{url: server.URL, httpClient: server.Client()}
+	err := sink.ResolveIncident(context.Background(), Args{RoutingKey: "testRoutingKey", DedupKey: "testDedupKey"})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "resolve", apiErr.Action)
+	require.Contains(t, err.Error(), "502")
+}
+
+// TestStdoutSinkPrintsEventJSON verifies that the stdout sink writes a JSON
+// line describing the event, for dry-run CI validation.
+func TestStdoutSinkPrintsEventJSON(t *testing.T) {
+	var buf bytes.Buffer
+	sink := &stdoutSink{out: &buf}
+
+	err := sink.AcknowledgeIncident(context.Background(), Args{RoutingKey: "testRoutingKey", DedupKey: "testDedupKey"})
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "acknowledge")
+	require.Contains(t, buf.String(), "testDedupKey")
+}
+
+// TestStdoutSinkRedactsRoutingKey verifies that the live routing key never
+// appears in the stdout sink's output, since "dry-run CI validation" output
+// is commonly retained or public in pipeline logs.
+func TestStdoutSinkRedactsRoutingKey(t *testing.T) {
+	var buf bytes.Buffer
+	sink := &stdoutSink{out: &buf}
+
+	err := sink.TriggerIncident(context.Background(), Args{RoutingKey: "SUPER-SECRET-ROUTING-KEY", DedupKey: "testDedupKey"})
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "SUPER-SECRET-ROUTING-KEY")
+	require.Contains(t, buf.String(), redactedRoutingKey)
+}
+
+// TestWebhookSinkRedactsRoutingKey verifies that the live routing key never
+// appears in the body posted by the webhook sink.
+func TestWebhookSinkRedactsRoutingKey(t *testing.T) {
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := &webhookSink{url: server.URL, httpClient: server.Client()}
+	err := sink.TriggerIncident(context.Background(), Args{RoutingKey: "SUPER-SECRET-ROUTING-KEY", DedupKey: "testDedupKey"})
+
+	require.NoError(t, err)
+	require.NotContains(t, string(gotBody), "SUPER-SECRET-ROUTING-KEY")
+	require.Contains(t, string(gotBody), redactedRoutingKey)
+}
+
+// TestBuildV2EventOmitsPayloadExceptOnTrigger verifies that only the trigger
+// action carries the incident payload, links, and images; resolve and
+// acknowledge reference the incident by DedupKey alone.
+func TestBuildV2EventOmitsPayloadExceptOnTrigger(t *testing.T) {
+	args := Args{
+		RoutingKey: "testRoutingKey",
+		DedupKey:   "testDedupKey",
+		Links:      []interface{}{map[string]interface{}{"href": "https://example.com"}},
+	}
+
+	trigger := buildV2Event("trigger", args)
+	require.NotNil(t, trigger.Payload)
+	require.Len(t, trigger.Links, 1)
+
+	resolve := buildV2Event("resolve", args)
+	require.Nil(t, resolve.Payload)
+	require.Nil(t, resolve.Links)
+
+	acknowledge := buildV2Event("acknowledge", args)
+	require.Nil(t, acknowledge.Payload)
+	require.Nil(t, acknowledge.Links)
+}